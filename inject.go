@@ -2,12 +2,40 @@
 package inject
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+)
+
+// Sentinel errors wrapped by the errors that Invoke, InvokeNamed and Apply
+// return and that Get, MustGet, GetNamed and Provide panic with. Use errors.Is to test
+// for a specific cause; the offending reflect.Type is folded into the
+// message rather than carried structurally.
+var (
+	// ErrNotFound is returned when no value or provider can satisfy a
+	// requested reflect.Type.
+	ErrNotFound = errors.New("inject: no instance found")
+	// ErrCycle is returned when resolving a lazy provider chain loops back
+	// on a type that is already being resolved.
+	ErrCycle = errors.New("inject: cycle detected")
+	// ErrProviderFailed is returned when a lazy provider's final error
+	// return value is non-nil.
+	ErrProviderFailed = errors.New("inject: provider failed")
 )
 
 // Injector represents an interface for mapping and injecting dependencies into structs
 // and function arguments.
+//
+// An injector is safe for concurrent use. Reads (Get, MustGet, TryGet,
+// GetNamed, GetAll, GetAllUnique) served from values already mapped in the
+// injector run concurrently with each other under a read lock; GetAll and
+// GetAllUnique read the parent's values that way too. A read that has to
+// invoke a not-yet-resolved lazy provider, or a single-type read that finds
+// no match among the injector's own values (for GetNamed with a name, among
+// those mapped under that name), along with every TypeMapper mutator and
+// every Invoke/Apply call, serializes on a single write lock.
 type Injector interface {
 	Applicator
 	Invoker
@@ -16,6 +44,14 @@ type Injector interface {
 	// dependency in its Type map it will check its parent before returning an
 	// error.
 	SetParent(Injector)
+	// Child returns a new Injector with the receiver pre-set as its parent,
+	// suitable as a request-scoped container that falls back to the
+	// receiver's mappings without mutating them.
+	Child() Injector
+	// Scoped creates a Child of the receiver, runs fn with it, and discards
+	// the child's values once fn returns. It lets middleware inject
+	// request-local dependencies without leaking them into the parent.
+	Scoped(fn func(Injector))
 }
 
 // Applicator represents an interface for mapping dependencies to a struct.
@@ -33,6 +69,27 @@ type Invoker interface {
 	// a slice of reflect.Value representing the returned values of the function.
 	// Returns an error if the injection fails.
 	Invoke(interface{}) ([]reflect.Value, error)
+	// InvokeNamed behaves like Invoke, except argNames[i], when non-empty,
+	// is used to resolve the i-th argument by name instead of by type alone.
+	// A shorter argNames, or an empty entry, falls back to an unnamed lookup
+	// for that argument.
+	InvokeNamed(f interface{}, argNames []string) ([]reflect.Value, error)
+	// Bind assigns fnPtr, a pointer to a func variable, a wrapper around fn
+	// built with reflect.MakeFunc. fn's leading parameters, one per input of
+	// fnPtr's own function type, are supplied positionally by the caller
+	// when the bound variable is invoked; fn's remaining parameters are
+	// resolved from the injector on every call. This lets callers use
+	// ordinary Go call syntax instead of reflect boilerplate around Invoke:
+	//
+	//	var handle func(*http.Request) string
+	//	inj.Bind(&handle, func(req *http.Request, db *DB) string { ... })
+	//	handle(req)
+	Bind(fnPtr interface{}, fn interface{})
+	// BindMethod behaves like Bind, but wraps an unbound method expression
+	// (e.g. (*Controller).Handle) whose receiver is the method's first
+	// parameter. The receiver is always resolved from the injector, never
+	// supplied by the caller.
+	BindMethod(fnPtr interface{}, method interface{})
 }
 
 // TypeMapper represents an interface for mapping interface{} values based on type.
@@ -43,8 +100,24 @@ type TypeMapper interface {
 	// This is really only useful for mapping a value as an interface, as interfaces
 	// cannot at this time be referenced directly without a pointer.
 	MapTo(interface{}, interface{}) TypeMapper
+	// Maps the interface{} value based on its immediate type from reflect.TypeOf,
+	// qualified by name so it can be disambiguated from other values of the
+	// same type.
+	MapNamed(name string, val interface{}) TypeMapper
+	// Maps the interface{} value based on the pointer of an Interface provided,
+	// qualified by name so it can be disambiguated from other values mapped
+	// to the same interface.
+	MapToNamed(name string, val interface{}, ifacePtr interface{}) TypeMapper
 	// Provide the dynamic type of interface{} returns,
 	Provide(interface{}) TypeMapper
+	// MustProvide is the explicit, intentionally-panicking name for Provide,
+	// which is kept only as its back-compat alias.
+	MustProvide(ctor interface{}) TypeMapper
+	// ProvideLazy records ctor's input and result types without invoking it.
+	// The constructor is only called the first time one of its result types
+	// is requested through Get, Invoke or Apply, at which point its own
+	// inputs are resolved from the injector first.
+	ProvideLazy(ctor interface{}) TypeMapper
 	// Provides a possibility to directly insert a mapping based on type and value.
 	// This makes it possible to directly map type arguments not possible to instantiate
 	// with reflect like unidirectional channels.
@@ -52,21 +125,71 @@ type TypeMapper interface {
 	// Returns the Value that is mapped to the current type. Returns a zeroed Value if
 	// the Type has not been mapped.
 	Get(reflect.Type) reflect.Value
+	// MustGet is the explicit, intentionally-panicking name for Get, which
+	// is kept only as its back-compat alias.
+	MustGet(t reflect.Type) reflect.Value
+	// TryGet behaves like Get, but returns ok=false instead of panicking
+	// when t cannot be resolved.
+	TryGet(t reflect.Type) (reflect.Value, bool)
+	// GetNamed returns the Value mapped to t under name. If no value was
+	// mapped under that name, it falls back to an unnamed Get.
+	GetNamed(t reflect.Type, name string) reflect.Value
 	// Returns all the Values that are mapped to the current interface. Returns an empty slice if
-	// the Type has not been mapped.
+	// the Type has not been mapped. Any ProvideLazy constructor whose result
+	// implements the interface is resolved as needed to produce its value.
 	GetAll(reflect.Type) []reflect.Value
+	// GetAllUnique behaves like GetAll, except a value from a parent scope is
+	// suppressed if a value of the same dynamic type was already found in a
+	// closer scope, instead of always appending both.
+	GetAllUnique(reflect.Type) []reflect.Value
 }
 
 type instance struct {
-	tp reflect.Type
-	vl reflect.Value
+	tp   reflect.Type
+	vl   reflect.Value
+	name string
+}
+
+// lazyProvider is a constructor registered via ProvideLazy. It is kept
+// around, unexpanded, until one of its result types is actually needed.
+type lazyProvider struct {
+	ctor    reflect.Value
+	ctorT   reflect.Type
+	results []reflect.Type
+	// resolveMu serializes invocations of ctor itself: it's held only
+	// across the unlocked window where ctor actually runs (see
+	// resolveProvider), so a second caller resolving the same provider
+	// waits for and reuses the first's result instead of invoking ctor a
+	// second time.
+	resolveMu sync.Mutex
+	resolved  bool
+	// resolving is true for the window ctor is actually executing (guarded
+	// by the owning injector's mu, like resolved). resolveAllProviders uses
+	// it to recognize a provider that is already being built by the current
+	// call stack so a ctor that enumerates its own interface via GetAll or
+	// GetAllUnique doesn't try to lock resolveMu a second time on itself.
+	resolving bool
 }
 
+// injector's mu is a read-write lock, but only the single-type read paths
+// (Get/MustGet/TryGet/GetNamed/GetAll/GetAllUnique) get real read
+// concurrency, via getCached/getNamedCached/collectAll/collectAllUnique: a
+// value already in values is returned under RLock alone. Any of those calls
+// that would need to invoke an unresolved lazy provider falls back to the
+// full write lock, since resolution appends to values. Invoke and Apply
+// always take the write lock outright: each resolves several arguments or
+// fields in one pass, at least one of which may need to trigger provider
+// resolution, so there's no cheap way to know in advance that the whole
+// call can be served read-only.
 type injector struct {
-	values []instance
-	parent Injector
+	mu        sync.RWMutex
+	values    []instance
+	providers []*lazyProvider
+	parent    Injector
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // InterfaceOf dereferences a pointer to an Interface type.
 // It panics if value is not an pointer to an interface.
 func InterfaceOf(value interface{}) reflect.Type {
@@ -90,32 +213,230 @@ func New() Injector {
 	}
 }
 
+// Child returns a new Injector with the receiver pre-set as its parent.
+// Values mapped into the child are local to it; lookups that miss fall
+// through to the receiver.
+func (i *injector) Child() Injector {
+	child := New()
+	child.SetParent(i)
+	return child
+}
+
+// Scoped creates a Child of the receiver, runs fn with it, and discards the
+// child's values once fn returns, so middleware can inject request-local
+// dependencies without mutating the receiver.
+func (i *injector) Scoped(fn func(Injector)) {
+	fn(i.Child())
+}
+
 // Invoke attempts to call the interface{} provided as a function,
 // providing dependencies for function arguments based on Type.
 // Returns a slice of reflect.Value representing the returned values of the function.
 // Returns an error if the injection fails.
 // It panics if f is not a function
 func (inj *injector) Invoke(f interface{}) ([]reflect.Value, error) {
+	return inj.InvokeNamed(f, nil)
+}
+
+// InvokeNamed behaves like Invoke, except argNames[i], when non-empty, is
+// used to resolve the i-th argument by name instead of by type alone.
+// A shorter argNames, or an empty entry, falls back to an unnamed lookup.
+func (inj *injector) InvokeNamed(f interface{}, argNames []string) ([]reflect.Value, error) {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+
+	return inj.invokeNamed(f, argNames)
+}
+
+func (inj *injector) invokeNamed(f interface{}, argNames []string) ([]reflect.Value, error) {
 	t := reflect.TypeOf(f)
 
-	var in = make([]reflect.Value, t.NumIn()) //Panic if t is not kind of Func
-	for i := 0; i < t.NumIn(); i++ {
+	numIn := t.NumIn() //Panic if t is not kind of Func
+	in := make([]reflect.Value, numIn)
+	for i := 0; i < numIn; i++ {
 		argType := t.In(i)
-		val := inj.Get(argType)
-		if !val.IsValid() {
-			return nil, fmt.Errorf("value not found for type %v", argType)
+
+		if argType.Kind() == reflect.Slice && argType.Elem().Kind() == reflect.Interface {
+			in[i] = inj.getAllSlice(argType)
+			continue
+		}
+
+		var name string
+		if i < len(argNames) {
+			name = argNames[i]
+		}
+
+		val, err := inj.getNamed(argType, name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("value not found for type %v: %w", argType, err)
 		}
 
 		in[i] = val
 	}
 
-	return reflect.ValueOf(f).Call(in), nil
+	fn := reflect.ValueOf(f)
+	if t.IsVariadic() && t.In(numIn-1).Elem().Kind() == reflect.Interface {
+		return fn.CallSlice(in), nil
+	}
+
+	return fn.Call(in), nil
+}
+
+// getAllSlice builds a slice of sliceType (a []I where I is an interface)
+// from every value getAll finds mapped to I.
+func (inj *injector) getAllSlice(sliceType reflect.Type) reflect.Value {
+	vals := inj.getAll(sliceType.Elem())
+
+	out := reflect.MakeSlice(sliceType, len(vals), len(vals))
+	for idx, v := range vals {
+		out.Index(idx).Set(v)
+	}
+
+	return out
+}
+
+// Bind assigns fnPtr a reflect.MakeFunc wrapper around fn, so that calling
+// the bound variable resolves fn's non-leading parameters from the
+// injector. See the Invoker interface doc for the calling convention.
+func (inj *injector) Bind(fnPtr interface{}, fn interface{}) {
+	fnVal := reflect.ValueOf(fn)
+	fnType := fnVal.Type()
+	if fnType.Kind() != reflect.Func {
+		panic("inject: Bind requires fn to be a func")
+	}
+
+	inj.bind(fnPtr, fnVal, fnType, 0)
+}
+
+// BindMethod behaves like Bind, but wraps an unbound method expression
+// (e.g. (*Controller).Handle), whose receiver is its first parameter. The
+// receiver is always resolved from the injector, never supplied by the
+// caller.
+func (inj *injector) BindMethod(fnPtr interface{}, method interface{}) {
+	methodVal := reflect.ValueOf(method)
+	methodType := methodVal.Type()
+	if methodType.Kind() != reflect.Func || methodType.NumIn() < 1 {
+		panic("inject: BindMethod requires an unbound method expression with a receiver parameter")
+	}
+
+	inj.bind(fnPtr, methodVal, methodType, 1)
+}
+
+// bind is the shared implementation behind Bind and BindMethod. injectFrom
+// is the count of fnType's leading parameters resolved from the injector
+// rather than matched positionally against fnPtr's own parameters;
+// BindMethod uses 1, to always inject the receiver.
+func (inj *injector) bind(fnPtr interface{}, fnVal reflect.Value, fnType reflect.Type, injectFrom int) {
+	ptrVal := reflect.ValueOf(fnPtr)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() || ptrVal.Elem().Kind() != reflect.Func {
+		panic("inject: Bind requires fnPtr to be a non-nil pointer to a func variable")
+	}
+
+	outType := ptrVal.Elem().Type()
+	provided := outType.NumIn()
+
+	if fnType.NumIn() < injectFrom+provided {
+		panic("inject: bound function has fewer parameters than the target function type")
+	}
+	if outType.NumOut() != fnType.NumOut() {
+		panic("inject: bound function's results do not match the target function type")
+	}
+
+	returnsErr := outType.NumOut() > 0 && outType.Out(outType.NumOut()-1) == errorType
+
+	wrapper := reflect.MakeFunc(outType, func(args []reflect.Value) []reflect.Value {
+		in := make([]reflect.Value, fnType.NumIn())
+
+		// All injected arguments for this call are resolved under a single
+		// lock acquisition, like InvokeNamed/Apply, so a concurrent Map
+		// between two of them can't hand this call a torn mix of
+		// before/after container state. fnVal itself is called outside the
+		// lock, matching resolveProvider's handling of arbitrary callee code.
+		var missing reflect.Type
+		inj.mu.Lock()
+		for idx := 0; idx < injectFrom; idx++ {
+			v, err := inj.get(fnType.In(idx), nil)
+			if err != nil {
+				missing = fnType.In(idx)
+				break
+			}
+			in[idx] = v
+		}
+		if missing == nil {
+			for idx := injectFrom + provided; idx < fnType.NumIn(); idx++ {
+				v, err := inj.get(fnType.In(idx), nil)
+				if err != nil {
+					missing = fnType.In(idx)
+					break
+				}
+				in[idx] = v
+			}
+		}
+		inj.mu.Unlock()
+
+		if missing != nil {
+			return bindNotFound(outType, returnsErr, missing)
+		}
+
+		copy(in[injectFrom:injectFrom+provided], args)
+
+		return fnVal.Call(in)
+	})
+
+	ptrVal.Elem().Set(wrapper)
+}
+
+// bindNotFound builds the zeroed result slice for a bound call that is
+// missing an injected dependency. If outType's own last result is an
+// error, the failure is surfaced there; otherwise it panics, since there is
+// nowhere else in outType's signature to report it.
+func bindNotFound(outType reflect.Type, returnsErr bool, missing reflect.Type) []reflect.Value {
+	err := fmt.Errorf("%w: %v", ErrNotFound, missing)
+	if !returnsErr {
+		panic(err)
+	}
+
+	out := make([]reflect.Value, outType.NumOut())
+	for i := 0; i < len(out)-1; i++ {
+		out[i] = reflect.Zero(outType.Out(i))
+	}
+	out[len(out)-1] = reflect.ValueOf(err)
+
+	return out
+}
+
+// injectTag is the parsed form of an `inject:"..."` struct tag value: an
+// optional name, and an `optional` option that leaves the field untouched
+// instead of erroring when nothing can be resolved for it.
+type injectTag struct {
+	name     string
+	optional bool
+}
+
+// parseInjectTag splits a comma-separated inject tag value (e.g.
+// "name,optional") into its name and optional components. Either may be
+// absent; order doesn't matter.
+func parseInjectTag(raw string) injectTag {
+	var tag injectTag
+	for _, part := range strings.Split(raw, ",") {
+		if part == "optional" {
+			tag.optional = true
+			continue
+		}
+		if part != "" {
+			tag.name = part
+		}
+	}
+	return tag
 }
 
 // Maps dependencies in the Type map to each field in the struct
 // that is tagged with 'inject'.
 // Returns an error if the injection fails.
 func (inj *injector) Apply(val interface{}) error {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+
 	v := reflect.ValueOf(val)
 
 	for v.Kind() == reflect.Ptr {
@@ -131,15 +452,32 @@ func (inj *injector) Apply(val interface{}) error {
 	for i := 0; i < v.NumField(); i++ {
 		f := v.Field(i)
 		structField := t.Field(i)
-		if f.CanSet() && (structField.Tag == "inject" || structField.Tag.Get("inject") != "") {
-			ft := f.Type()
-			v := inj.Get(ft)
-			if !v.IsValid() {
-				return fmt.Errorf("value not found for type %v", ft)
-			}
+		if !f.CanSet() {
+			continue
+		}
+
+		raw, hasTag := structField.Tag.Get("inject"), structField.Tag == "inject"
+		if !hasTag && raw == "" {
+			continue
+		}
+
+		tag := parseInjectTag(raw)
+		ft := f.Type()
 
-			f.Set(v)
+		if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Interface {
+			f.Set(inj.getAllSlice(ft))
+			continue
+		}
+
+		v, err := inj.getNamed(ft, tag.name, nil)
+		if err != nil {
+			if tag.optional {
+				continue
+			}
+			return fmt.Errorf("value not found for type %v: %w", ft, err)
 		}
+
+		f.Set(v)
 	}
 
 	return nil
@@ -148,83 +486,529 @@ func (inj *injector) Apply(val interface{}) error {
 // Maps the concrete value of val to its dynamic type using reflect.TypeOf,
 // It returns the TypeMapper registered in.
 func (i *injector) Map(val interface{}) TypeMapper {
-	i.values = append(i.values, instance{reflect.TypeOf(val), reflect.ValueOf(val)})
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.values = append(i.values, instance{reflect.TypeOf(val), reflect.ValueOf(val), ""})
 	return i
 }
 
 func (i *injector) MapTo(val interface{}, ifacePtr interface{}) TypeMapper {
-	i.values = append(i.values, instance{InterfaceOf(ifacePtr), reflect.ValueOf(val)})
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.values = append(i.values, instance{InterfaceOf(ifacePtr), reflect.ValueOf(val), ""})
 	return i
 }
 
-// Provide the dynamic type of provider returns,
-// It returns the TypeMapper registered in.
+// MapNamed behaves like Map, qualified by name so it can be disambiguated
+// from other values of the same type via GetNamed or an `inject:"name"` tag.
+func (i *injector) MapNamed(name string, val interface{}) TypeMapper {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.values = append(i.values, instance{reflect.TypeOf(val), reflect.ValueOf(val), name})
+	return i
+}
+
+// MapToNamed behaves like MapTo, qualified by name so it can be
+// disambiguated from other values mapped to the same interface.
+func (i *injector) MapToNamed(name string, val interface{}, ifacePtr interface{}) TypeMapper {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.values = append(i.values, instance{InterfaceOf(ifacePtr), reflect.ValueOf(val), name})
+	return i
+}
+
+// Provide the dynamic type of provider returns. It panics if invoking
+// provider failed. It is a back-compat alias for MustProvide.
 func (inj *injector) Provide(provider interface{}) TypeMapper {
-	results, err := inj.Invoke(reflect.ValueOf(provider).Interface())
+	return inj.MustProvide(provider)
+}
+
+// MustProvide behaves like Provide: it invokes ctor immediately and maps
+// each of its results, panicking if ctor's own dependencies cannot be
+// resolved or ctor itself cannot be called.
+func (inj *injector) MustProvide(ctor interface{}) TypeMapper {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+
+	results, err := inj.invokeNamed(reflect.ValueOf(ctor).Interface(), nil)
 	if err != nil {
 		panic(err)
 	}
 
 	for _, result := range results {
 		resultType := result.Type()
-		inj.values = append(inj.values, instance{resultType, result})
+		inj.values = append(inj.values, instance{resultType, result, ""})
 	}
 
 	return inj
 }
 
+// ProvideLazy records the reflect.Type of ctor's inputs and results without
+// invoking it. It returns the TypeMapper registered in.
+//
+// ctor is only called the first time Get, Invoke or Apply resolves one of
+// its result types; its own inputs are resolved from the injector first,
+// recursively expanding any other lazy providers they depend on. If ctor's
+// last return value is an error, a non-nil error short-circuits resolution
+// instead of being cached as a value.
+func (inj *injector) ProvideLazy(ctor interface{}) TypeMapper {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+
+	ctorVal := reflect.ValueOf(ctor)
+	ctorT := ctorVal.Type()
+
+	results := make([]reflect.Type, ctorT.NumOut())
+	for i := range results {
+		results[i] = ctorT.Out(i)
+	}
+
+	inj.providers = append(inj.providers, &lazyProvider{
+		ctor:    ctorVal,
+		ctorT:   ctorT,
+		results: results,
+	})
+
+	return inj
+}
+
 // Maps the given reflect.Type to the given reflect.Value and returns
 // the Typemapper the mapping has been registered in.
 // It panics if invoke provider failed.
 func (i *injector) Set(typ reflect.Type, val reflect.Value) TypeMapper {
-	i.values = append(i.values, instance{typ, val})
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.values = append(i.values, instance{typ, val, ""})
 	return i
 }
 
+// Get returns the Value mapped to t, panicking if none can be resolved. It
+// is a back-compat alias for MustGet; TryGet reports ok=false instead.
 func (i *injector) Get(t reflect.Type) reflect.Value {
+	return i.MustGet(t)
+}
+
+// MustGet behaves like Get: it returns the Value mapped to t and panics if
+// none can be resolved.
+func (i *injector) MustGet(t reflect.Type) reflect.Value {
+	i.mu.RLock()
+	v, ok := i.getCached(t)
+	i.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	v, err := i.get(t, nil)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// TryGet behaves like Get, but returns ok=false instead of panicking when t
+// cannot be resolved.
+func (i *injector) TryGet(t reflect.Type) (reflect.Value, bool) {
+	i.mu.RLock()
+	if v, ok := i.getCached(t); ok {
+		i.mu.RUnlock()
+		return v, true
+	}
+	i.mu.RUnlock()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	v, err := i.get(t, nil)
+	return v, err == nil
+}
+
+// getCached returns the value already mapped to t, without touching lazy
+// providers or the parent. It only reads i.values, so it's safe to call
+// under a read lock as a fast path before falling back to the full,
+// write-locked get, which also has to account for providers that still need
+// resolving and parent delegation.
+func (i *injector) getCached(t reflect.Type) (reflect.Value, bool) {
 	for _, inst := range i.values {
 		if inst.tp == t && inst.vl.IsValid() {
-			return inst.vl
+			return inst.vl, true
 		}
 	}
 
-	// no concrete types found, try to find implementors
-	// if t is an interface
 	if t.Kind() == reflect.Interface {
 		for _, inst := range i.values {
 			if inst.tp.Implements(t) && inst.vl.IsValid() {
-				return inst.vl
+				return inst.vl, true
 			}
 		}
 	}
 
+	return reflect.Value{}, false
+}
+
+// get resolves t, assuming the caller already holds i.mu. chain is the set
+// of types already being resolved by the current top-level call, used to
+// detect a lazy provider cycle; pass nil from a fresh entry point.
+func (i *injector) get(t reflect.Type, chain []reflect.Type) (reflect.Value, error) {
+	if v, ok := i.getCached(t); ok {
+		return v, nil
+	}
+
+	// no mapped value yet, see if a lazy provider produces t
+	if p := i.findProvider(t); p != nil {
+		return i.resolveProvider(t, p, chain)
+	}
+
 	// Still no type found, try to look it up on the parent
 	if i.parent != nil {
-		return i.parent.Get(t)
+		if v, ok := i.parent.TryGet(t); ok {
+			return v, nil
+		}
+		return reflect.Value{}, fmt.Errorf("%w: %v", ErrNotFound, t)
 	}
 
-	panic(fmt.Sprint("no instance found for ", t))
+	return reflect.Value{}, fmt.Errorf("%w: %v", ErrNotFound, t)
+}
+
+// GetNamed returns the Value mapped to t under name, as registered by
+// MapNamed or MapToNamed. If no named mapping matches, or name is empty,
+// it falls back to an unnamed Get. It panics if nothing can be resolved.
+func (i *injector) GetNamed(t reflect.Type, name string) reflect.Value {
+	i.mu.RLock()
+	v, ok := i.getNamedCached(t, name)
+	i.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	v, err := i.getNamed(t, name, nil)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// getNamedCached is the read-only part of getNamed: a named lookup in
+// i.values, falling back to the unnamed getCached when name is empty. A
+// named lookup that misses locally always defers to the full, write-locked
+// getNamed, since consulting the parent isn't worth duplicating here.
+func (i *injector) getNamedCached(t reflect.Type, name string) (reflect.Value, bool) {
+	if name == "" {
+		return i.getCached(t)
+	}
+
+	for _, inst := range i.values {
+		if inst.name != name || !inst.vl.IsValid() {
+			continue
+		}
+		if inst.tp == t || (t.Kind() == reflect.Interface && inst.tp.Implements(t)) {
+			return inst.vl, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func (i *injector) getNamed(t reflect.Type, name string, chain []reflect.Type) (reflect.Value, error) {
+	if name != "" {
+		if v, ok := i.getNamedCached(t, name); ok {
+			return v, nil
+		}
+
+		if i.parent != nil {
+			if v, ok := tryParentNamed(i.parent, t, name); ok {
+				return v, nil
+			}
+		}
+	}
+
+	return i.get(t, chain)
+}
+
+// tryParentNamed looks t up under name in parent and its ancestors, matching
+// only values mapped under that name, so callers can fall back to an
+// unnamed lookup when none matches. An ancestor that is not an *injector is
+// not searched by name.
+func tryParentNamed(parent Injector, t reflect.Type, name string) (reflect.Value, bool) {
+	for parent != nil {
+		p, ok := parent.(*injector)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		p.mu.RLock()
+		v, found := p.getNamedCached(t, name)
+		next := p.parent
+		p.mu.RUnlock()
+		if found {
+			return v, true
+		}
+		parent = next
+	}
+	return reflect.Value{}, false
+}
+
+// findProvider returns the lazy provider whose results produce t, preferring
+// an exact type match and falling back to interface satisfaction.
+func (i *injector) findProvider(t reflect.Type) *lazyProvider {
+	for _, p := range i.providers {
+		for _, rt := range p.results {
+			if rt == t {
+				return p
+			}
+		}
+	}
+
+	if t.Kind() == reflect.Interface {
+		for _, p := range i.providers {
+			for _, rt := range p.results {
+				if rt.Implements(t) {
+					return p
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveProvider invokes p, recursively resolving its inputs first, and
+// caches every value it returns. chain is the set of types already being
+// resolved by the current top-level call; if t is already in it, p depends
+// on itself and a cycle is reported instead of recursing forever.
+func (i *injector) resolveProvider(t reflect.Type, p *lazyProvider, chain []reflect.Type) (reflect.Value, error) {
+	for _, seen := range chain {
+		if seen == t {
+			return reflect.Value{}, fmt.Errorf("%w: %v: %s", ErrCycle, t, cyclePath(chain, t))
+		}
+	}
+	nextChain := append(append([]reflect.Type(nil), chain...), t)
+
+	in := make([]reflect.Value, p.ctorT.NumIn())
+	for idx := range in {
+		argT := p.ctorT.In(idx)
+		v, err := i.get(argT, nextChain)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("inject: resolving %v: %w", t, err)
+		}
+		in[idx] = v
+	}
+
+	// ctor is arbitrary caller code that may itself call back into this
+	// injector (Get, Invoke, Apply...), and i.mu is not reentrant, so it must
+	// not be held across the call. Invocation of this specific provider is
+	// serialized on p.resolveMu instead, so a second caller resolving the
+	// same type while ctor is in flight waits for and reuses the result
+	// rather than racing the constructor.
+	i.mu.Unlock()
+	p.resolveMu.Lock()
+	i.mu.Lock()
+
+	if p.resolved {
+		p.resolveMu.Unlock()
+		return i.get(t, chain)
+	}
+
+	p.resolving = true
+	out := func() []reflect.Value {
+		i.mu.Unlock()
+		defer func() {
+			i.mu.Lock()
+			p.resolving = false
+			p.resolveMu.Unlock()
+		}()
+		return p.ctor.Call(in)
+	}()
+
+	if n := len(out); n > 0 && out[n-1].Type() == errorType {
+		if errVal := out[n-1]; !errVal.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%w: %v: %w", ErrProviderFailed, t, errVal.Interface().(error))
+		}
+		out = out[:n-1]
+	}
+
+	var result reflect.Value
+	for idx, rv := range out {
+		rt := p.results[idx]
+		i.values = append(i.values, instance{rt, rv, ""})
+		if rt == t || (t.Kind() == reflect.Interface && rt.Implements(t)) {
+			result = rv
+		}
+	}
+	p.resolved = true
+
+	return result, nil
+}
+
+// cyclePath renders the in-progress resolution chain followed by the type
+// that closes the cycle, e.g. "A -> B -> A".
+func cyclePath(stack []reflect.Type, closing reflect.Type) string {
+	s := ""
+	for _, t := range stack {
+		s += t.String() + " -> "
+	}
+	return s + closing.String()
 }
 
 func (i *injector) GetAll(t reflect.Type) []reflect.Value {
+	if t.Kind() != reflect.Interface {
+		panic("cannot get all implementors for non interface type")
+	}
+
+	i.mu.RLock()
+	if !i.needsProviderResolution(t) {
+		values := i.collectAll(t)
+		i.mu.RUnlock()
+		return values
+	}
+	i.mu.RUnlock()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.getAll(t)
+}
+
+// needsProviderResolution reports whether some not-yet-resolved, non-in-
+// flight lazy provider could produce an implementor of t. When it doesn't,
+// GetAll/GetAllUnique can be served entirely from i.values under a read
+// lock, since nothing needs to be invoked or cached.
+func (i *injector) needsProviderResolution(t reflect.Type) bool {
+	for _, p := range i.providers {
+		if p.resolved || p.resolving {
+			continue
+		}
+		for _, rt := range p.results {
+			if rt == t || rt.Implements(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveAllProviders invokes every lazy provider capable of producing t (or
+// an implementor of t, when t is an interface) that hasn't already been
+// resolved, so getAll/getAllUnique can see values that only exist behind
+// ProvideLazy. A provider whose results are already cached in i.values is
+// left alone so it's never invoked twice. A provider that is already mid-
+// construction (its ctor, running on this very call stack, reentrantly
+// called GetAll/GetAllUnique on an interface its own result implements) is
+// skipped rather than resolved: resolveProvider would otherwise try to lock
+// that provider's resolveMu a second time on the same goroutine and block
+// forever. The in-progress provider simply isn't counted among its own
+// peers yet, which is the best an enumeration can do while it's still being
+// built.
+func (i *injector) resolveAllProviders(t reflect.Type) {
+	for _, p := range i.providers {
+		if p.resolved || p.resolving {
+			continue
+		}
+
+		matches := false
+		for _, rt := range p.results {
+			if rt == t || rt.Implements(t) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		if _, err := i.resolveProvider(t, p, nil); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (i *injector) getAll(t reflect.Type) []reflect.Value {
+	i.resolveAllProviders(t)
+
+	return i.collectAll(t)
+}
+
+// collectAll is the read-only part of getAll: scanning i.values and
+// recursing into the parent, without touching lazy providers. It's safe to
+// call under a read lock.
+func (i *injector) collectAll(t reflect.Type) []reflect.Value {
 	var values []reflect.Value
 
+	for _, inst := range i.values {
+		if inst.tp.Implements(t) && inst.vl.IsValid() {
+			values = append(values, inst.vl)
+		}
+	}
+
+	if i.parent != nil {
+		values = append(values, i.parent.GetAll(t)...)
+	}
+
+	return values
+}
+
+// GetAllUnique behaves like GetAll, except a value from a parent scope is
+// suppressed if a value of the same dynamic type was already found in a
+// closer scope.
+func (i *injector) GetAllUnique(t reflect.Type) []reflect.Value {
 	if t.Kind() != reflect.Interface {
 		panic("cannot get all implementors for non interface type")
 	}
 
-	if t.Kind() == reflect.Interface {
-		for _, inst := range i.values {
-			if inst.tp.Implements(t) && inst.vl.IsValid() {
-				values = append(values, inst.vl)
-			}
+	i.mu.RLock()
+	if !i.needsProviderResolution(t) {
+		values := i.collectAllUnique(t, make(map[reflect.Type]bool))
+		i.mu.RUnlock()
+		return values
+	}
+	i.mu.RUnlock()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.getAllUnique(t, make(map[reflect.Type]bool))
+}
+
+func (i *injector) getAllUnique(t reflect.Type, seen map[reflect.Type]bool) []reflect.Value {
+	i.resolveAllProviders(t)
+
+	return i.collectAllUnique(t, seen)
+}
+
+// collectAllUnique is the read-only part of getAllUnique: scanning i.values
+// and recursing into the parent with dedup, without touching lazy
+// providers. It's safe to call under a read lock.
+func (i *injector) collectAllUnique(t reflect.Type, seen map[reflect.Type]bool) []reflect.Value {
+	dynamicType := func(v reflect.Value) reflect.Type {
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			return v.Elem().Type()
+		}
+		return v.Type()
+	}
+
+	var values []reflect.Value
+
+	for _, inst := range i.values {
+		if inst.tp.Implements(t) && inst.vl.IsValid() {
+			seen[dynamicType(inst.vl)] = true
+			values = append(values, inst.vl)
 		}
 	}
 
 	if i.parent != nil {
-		parentVals := i.parent.GetAll(t)
-		for i := range parentVals {
-			values = append(values, parentVals[i])
+		for _, v := range i.parent.GetAllUnique(t) {
+			if !seen[dynamicType(v)] {
+				values = append(values, v)
+			}
 		}
 	}
 
@@ -232,5 +1016,8 @@ func (i *injector) GetAll(t reflect.Type) []reflect.Value {
 }
 
 func (i *injector) SetParent(parent Injector) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
 	i.parent = parent
 }
@@ -0,0 +1,460 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestConcurrentInvokeChildScopes exercises a shared parent injector with
+// many per-request Child scopes invoked concurrently, as called for by the
+// original Child/Scoped request: each goroutine maps its own request-local
+// value into its own child and must only ever see that value, never another
+// goroutine's.
+func TestConcurrentInvokeChildScopes(t *testing.T) {
+	parent := New()
+	parent.Map("shared")
+
+	const n = 64
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parent.Scoped(func(child Injector) {
+				child.MapNamed("request", i)
+
+				_, err := child.Invoke(func(shared string, req int) {
+					if shared != "shared" {
+						errs <- fmt.Errorf("goroutine %d: got shared=%q", i, shared)
+						return
+					}
+					if req != i {
+						errs <- fmt.Errorf("goroutine %d: saw request value %d", i, req)
+					}
+				})
+				if err != nil {
+					errs <- err
+				}
+			})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// TestProvideLazyReentrant is a regression test for a deadlock where a
+// ProvideLazy constructor calling back into the same injector (a completely
+// ordinary thing to do) hung forever because the injector's lock was held
+// across the constructor call.
+func TestProvideLazyReentrant(t *testing.T) {
+	inj := New()
+	inj.Map(7)
+	inj.ProvideLazy(func() *int {
+		n := inj.MustGet(reflect.TypeOf(0)).Interface().(int) * 2
+		return &n
+	})
+
+	done := make(chan *int, 1)
+	go func() {
+		done <- inj.MustGet(reflect.TypeOf((*int)(nil))).Interface().(*int)
+	}()
+
+	select {
+	case got := <-done:
+		if *got != 14 {
+			t.Errorf("got %d, want 14", *got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("MustGet deadlocked resolving a reentrant ProvideLazy constructor")
+	}
+}
+
+// TestConcurrentProvideLazySameType is a regression test for two goroutines
+// concurrently resolving the same not-yet-resolved ProvideLazy type: the
+// second caller must wait for and reuse the first's result instead of
+// tripping a spurious ErrCycle or invoking ctor a second time.
+func TestConcurrentProvideLazySameType(t *testing.T) {
+	inj := New()
+
+	var calls int32
+	inj.ProvideLazy(func() *int {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(100 * time.Millisecond)
+		n := 99
+		return &n
+	})
+
+	ptrType := reflect.TypeOf((*int)(nil))
+	var wg sync.WaitGroup
+	results := make([]*int, 2)
+	for i := 0; i < 2; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = inj.MustGet(ptrType).Interface().(*int)
+		}()
+	}
+	wg.Wait()
+
+	if results[0] != results[1] {
+		t.Errorf("got two different pointers %p and %p, want the same cached instance", results[0], results[1])
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("ctor called %d times, want 1", got)
+	}
+}
+
+// TestGetAllIncludesLazyProviders is a regression test for GetAll/GetAllUnique
+// silently ignoring implementors that were only ever registered via
+// ProvideLazy instead of Map/MapTo/Provide.
+func TestGetAllIncludesLazyProviders(t *testing.T) {
+	type Handler interface{ Handle() string }
+
+	inj := New()
+	inj.Map(eagerHandler{})
+	inj.ProvideLazy(func() Handler { return lazyHandler{} })
+
+	handlerType := InterfaceOf((*Handler)(nil))
+
+	all := inj.GetAll(handlerType)
+	if len(all) != 2 {
+		t.Fatalf("got %d handlers, want 2 (eager + lazy)", len(all))
+	}
+
+	unique := inj.GetAllUnique(handlerType)
+	if len(unique) != 2 {
+		t.Fatalf("got %d unique handlers, want 2 (eager + lazy)", len(unique))
+	}
+}
+
+// TestGetAllReentrantFromProvider is a regression test for a deadlock where
+// a ProvideLazy constructor called GetAll on an interface its own result
+// implements (a natural "enumerate my peers" pattern for composite
+// handlers). The reentrant GetAll re-entered resolveAllProviders for the
+// same, still mid-construction provider and tried to lock its resolveMu a
+// second time on the same goroutine, hanging forever.
+func TestGetAllReentrantFromProvider(t *testing.T) {
+	type Lister interface{ Peers() int }
+
+	inj := New()
+	listerType := InterfaceOf((*Lister)(nil))
+	inj.ProvideLazy(func() Lister {
+		return compositeLister{peers: len(inj.GetAll(listerType))}
+	})
+
+	done := make(chan Lister, 1)
+	go func() {
+		done <- inj.MustGet(listerType).Interface().(Lister)
+	}()
+
+	select {
+	case got := <-done:
+		if got.Peers() != 0 {
+			t.Errorf("got %d peers, want 0 (the provider isn't counted among its own peers while still under construction)", got.Peers())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetAll deadlocked when called reentrantly from inside a ProvideLazy constructor")
+	}
+}
+
+type compositeLister struct{ peers int }
+
+func (c compositeLister) Peers() int { return c.peers }
+
+// TestProvideLazyCycleDetection is a regression test for ProvideLazy's cycle
+// detection: two providers whose constructors depend on each other's result
+// type must fail with ErrCycle instead of recursing forever.
+func TestProvideLazyCycleDetection(t *testing.T) {
+	type A struct{}
+	type B struct{}
+
+	inj := New()
+	inj.ProvideLazy(func(B) A { return A{} })
+	inj.ProvideLazy(func(A) B { return B{} })
+
+	if _, ok := inj.TryGet(reflect.TypeOf(A{})); ok {
+		t.Fatal("TryGet reported success resolving a cyclic provider graph")
+	}
+
+	_, err := inj.(*injector).get(reflect.TypeOf(A{}), nil)
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("got err %v, want ErrCycle", err)
+	}
+}
+
+// TestProvideLazyErrorShortCircuits is a regression test for a ProvideLazy
+// constructor whose last return value is a non-nil error: resolution must
+// fail with ErrProviderFailed instead of caching the zero value, and a
+// second attempt must re-invoke the constructor rather than reusing a
+// short-circuited result.
+func TestProvideLazyErrorShortCircuits(t *testing.T) {
+	var calls int32
+	boom := errors.New("boom")
+
+	inj := New()
+	inj.ProvideLazy(func() (*int, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, boom
+	})
+
+	_, ok := inj.TryGet(reflect.TypeOf((*int)(nil)))
+	if ok {
+		t.Fatal("TryGet reported success for a provider that returned an error")
+	}
+
+	if _, ok := inj.TryGet(reflect.TypeOf((*int)(nil))); ok {
+		t.Fatal("TryGet reported success on a second attempt after a provider error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("ctor called %d times, want 2 (a provider error must not be cached)", got)
+	}
+}
+
+// TestGetNamedCollision is a regression test for MapNamed/GetNamed:
+// multiple values of the same type, disambiguated by name, must each be
+// retrievable by their own name, and an unnamed GetNamed call (or a plain
+// Get) must return the first value mapped for the type, which here is the
+// one mapped without a name.
+func TestGetNamedCollision(t *testing.T) {
+	inj := New()
+	inj.Map("unnamed")
+	inj.MapNamed("primary", "primary-value")
+	inj.MapNamed("secondary", "secondary-value")
+
+	stringType := reflect.TypeOf("")
+
+	if got := inj.GetNamed(stringType, "primary").Interface().(string); got != "primary-value" {
+		t.Errorf("GetNamed(primary) = %q, want %q", got, "primary-value")
+	}
+	if got := inj.GetNamed(stringType, "secondary").Interface().(string); got != "secondary-value" {
+		t.Errorf("GetNamed(secondary) = %q, want %q", got, "secondary-value")
+	}
+	if got := inj.GetNamed(stringType, "").Interface().(string); got != "unnamed" {
+		t.Errorf("GetNamed(\"\") = %q, want %q", got, "unnamed")
+	}
+	if got := inj.MustGet(stringType).Interface().(string); got != "unnamed" {
+		t.Errorf("MustGet = %q, want %q", got, "unnamed")
+	}
+}
+
+// TestGetNamedParentFallback is a regression test for a named lookup that
+// misses locally but is mapped on the parent: GetNamed must consult the
+// parent by name before falling back to an unnamed Get.
+func TestGetNamedParentFallback(t *testing.T) {
+	parent := New()
+	parent.MapNamed("dsn", "parent-dsn")
+
+	child := parent.Child()
+	child.Map("child-unnamed")
+
+	stringType := reflect.TypeOf("")
+	if got := child.GetNamed(stringType, "dsn").Interface().(string); got != "parent-dsn" {
+		t.Errorf("GetNamed(dsn) = %q, want %q (parent's named value)", got, "parent-dsn")
+	}
+}
+
+// TestApplyInjectTagName is a regression test for the `inject:"name"` struct
+// tag: a field tagged with a name must be filled from the matching
+// MapNamed/MapToNamed value instead of whatever was mapped unnamed.
+func TestApplyInjectTagName(t *testing.T) {
+	type target struct {
+		DSN string `inject:"dsn"`
+	}
+
+	inj := New()
+	inj.Map("unnamed-dsn")
+	inj.MapNamed("dsn", "named-dsn")
+
+	var v target
+	if err := inj.Apply(&v); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	if v.DSN != "named-dsn" {
+		t.Errorf("v.DSN = %q, want %q", v.DSN, "named-dsn")
+	}
+}
+
+// TestTryGetNotFound is a regression test for TryGet's non-panicking
+// contract: an unresolvable type must report ok=false rather than panicking
+// the way MustGet does.
+func TestTryGetNotFound(t *testing.T) {
+	inj := New()
+
+	if _, ok := inj.TryGet(reflect.TypeOf(0)); ok {
+		t.Fatal("TryGet reported success for an unmapped type")
+	}
+}
+
+// TestMustGetPanicsWithErrNotFound is a regression test for MustGet: it must
+// panic with an error satisfying errors.Is(err, ErrNotFound) when the type
+// can't be resolved, rather than some other panic value.
+func TestMustGetPanicsWithErrNotFound(t *testing.T) {
+	inj := New()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("MustGet did not panic for an unmapped type")
+		}
+		err, ok := r.(error)
+		if !ok || !errors.Is(err, ErrNotFound) {
+			t.Fatalf("panic value = %v, want an error satisfying errors.Is(err, ErrNotFound)", r)
+		}
+	}()
+	inj.MustGet(reflect.TypeOf(0))
+}
+
+// TestApplyOptionalLeavesZeroValue is a regression test for the
+// `inject:"optional"` tag: a field that can't be resolved must be left at
+// its zero value instead of making Apply return an error.
+func TestApplyOptionalLeavesZeroValue(t *testing.T) {
+	type target struct {
+		DB     *int   `inject:"optional"`
+		Logger string `inject:"logger,optional"`
+	}
+
+	inj := New()
+	inj.MapNamed("logger", "the-logger")
+
+	var v target
+	if err := inj.Apply(&v); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	if v.DB != nil {
+		t.Errorf("v.DB = %v, want nil (zero value, since no *int is mapped)", v.DB)
+	}
+	if v.Logger != "the-logger" {
+		t.Errorf("v.Logger = %q, want %q", v.Logger, "the-logger")
+	}
+}
+
+// TestBindMixesProvidedAndInjectedArgs is a regression test for Bind's
+// reflect.MakeFunc trampoline: leading parameters must be supplied
+// positionally by the caller on each call, while the remaining parameters
+// are resolved from the injector.
+func TestBindMixesProvidedAndInjectedArgs(t *testing.T) {
+	inj := New()
+	inj.Map("db-conn")
+
+	var handle func(string) string
+	inj.Bind(&handle, func(name string, db string) string {
+		return name + ":" + db
+	})
+
+	if got := handle("request-1"); got != "request-1:db-conn" {
+		t.Errorf("handle(%q) = %q, want %q", "request-1", got, "request-1:db-conn")
+	}
+}
+
+// TestBindMethod is a regression test for BindMethod: the receiver is always
+// resolved from the injector, never supplied by the caller.
+func TestBindMethod(t *testing.T) {
+	inj := New()
+	inj.Map(greeter{prefix: "hello, "})
+
+	var greet func(string) string
+	inj.BindMethod(&greet, greeter.Greet)
+
+	if got := greet("world"); got != "hello, world" {
+		t.Errorf("greet(%q) = %q, want %q", "world", got, "hello, world")
+	}
+}
+
+// TestBindNotFoundReturnsError is a regression test for bindNotFound: a
+// bound function whose target type returns an error surfaces a missing
+// injected dependency through that error instead of panicking.
+func TestBindNotFoundReturnsError(t *testing.T) {
+	inj := New()
+
+	var handle func(string) (string, error)
+	inj.Bind(&handle, func(name string, db string) (string, error) {
+		return name + db, nil
+	})
+
+	_, err := handle("request-1")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got err %v, want ErrNotFound (no string is mapped for the injected db param)", err)
+	}
+}
+
+// TestBindNotFoundPanics is a regression test for bindNotFound: a bound
+// function whose target type has no trailing error has nowhere to report a
+// missing injected dependency, so it panics instead.
+func TestBindNotFoundPanics(t *testing.T) {
+	inj := New()
+
+	var handle func(string) string
+	inj.Bind(&handle, func(name string, db string) string {
+		return name + db
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("handle did not panic for a missing injected dependency")
+		}
+	}()
+	handle("request-1")
+}
+
+type greeter struct{ prefix string }
+
+func (g greeter) Greet(name string) string { return g.prefix + name }
+
+// TestConcurrentGetAlreadyMapped is a regression test for the RLock fast
+// path on already-mapped values: many goroutines calling MustGet/GetAll on
+// values that need no lazy resolution must all see consistent results
+// without racing i.values.
+func TestConcurrentGetAlreadyMapped(t *testing.T) {
+	type Handler interface{ Handle() string }
+
+	inj := New()
+	inj.Map(42)
+	inj.Map(eagerHandler{})
+
+	handlerType := InterfaceOf((*Handler)(nil))
+	intType := reflect.TypeOf(0)
+
+	const n = 64
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := inj.MustGet(intType).Interface().(int); got != 42 {
+				errs <- fmt.Errorf("MustGet = %d, want 42", got)
+			}
+			if all := inj.GetAll(handlerType); len(all) != 1 {
+				errs <- fmt.Errorf("GetAll returned %d handlers, want 1", len(all))
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+type eagerHandler struct{}
+
+func (eagerHandler) Handle() string { return "eager" }
+
+type lazyHandler struct{}
+
+func (lazyHandler) Handle() string { return "lazy" }